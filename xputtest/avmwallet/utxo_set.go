@@ -4,33 +4,54 @@
 package avmwallet
 
 import (
+	"container/list"
 	"fmt"
 	"strings"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/math"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
 )
 
 // UTXOSet ...
 type UTXOSet struct {
 	// Key: The id of a UTXO
-	// Value: The index in UTXOs of that UTXO
-	utxoMap map[ids.ID]int
+	// Value: The position of that UTXO in this set
+	utxoMap map[ids.ID]*utxoEntry
+
+	// UTXOs in the order they were added to this set, oldest first
+	byAge   list.List
+	nextSeq uint64
 
 	// List of UTXOs in this set
-	// This can be used to iterate over. It should not be modified externally.
+	// This can be used to iterate over, although ForEach should be preferred.
+	// It should not be modified externally.
 	UTXOs []*avax.UTXO
 }
 
+type utxoEntry struct {
+	// The index in UTXOs of the UTXO
+	index int
+	// The order in which the UTXO was added to this set
+	seq uint64
+	// The element of the UTXO in byAge
+	age *list.Element
+}
+
 // Put ...
 func (us *UTXOSet) Put(utxo *avax.UTXO) {
 	if us.utxoMap == nil {
-		us.utxoMap = make(map[ids.ID]int)
+		us.utxoMap = make(map[ids.ID]*utxoEntry)
 	}
 	utxoID := utxo.InputID()
 	if _, ok := us.utxoMap[utxoID]; !ok {
-		us.utxoMap[utxoID] = len(us.UTXOs)
+		us.utxoMap[utxoID] = &utxoEntry{
+			index: len(us.UTXOs),
+			seq:   us.nextSeq,
+			age:   us.byAge.PushBack(utxo),
+		}
 		us.UTXOs = append(us.UTXOs, utxo)
+		us.nextSeq++
 	}
 }
 
@@ -39,8 +60,8 @@ func (us *UTXOSet) Get(id ids.ID) *avax.UTXO {
 	if us.utxoMap == nil {
 		return nil
 	}
-	if i, ok := us.utxoMap[id]; ok {
-		utxo := us.UTXOs[i]
+	if entry, ok := us.utxoMap[id]; ok {
+		utxo := us.UTXOs[entry.index]
 		return utxo
 	}
 	return nil
@@ -48,10 +69,11 @@ func (us *UTXOSet) Get(id ids.ID) *avax.UTXO {
 
 // Remove ...
 func (us *UTXOSet) Remove(id ids.ID) *avax.UTXO {
-	i, ok := us.utxoMap[id]
+	entry, ok := us.utxoMap[id]
 	if !ok {
 		return nil
 	}
+	i := entry.index
 	utxoI := us.UTXOs[i]
 
 	j := len(us.UTXOs) - 1
@@ -60,18 +82,100 @@ func (us *UTXOSet) Remove(id ids.ID) *avax.UTXO {
 	us.UTXOs[i] = us.UTXOs[j]
 	us.UTXOs = us.UTXOs[:j]
 
-	us.utxoMap[utxoJ.InputID()] = i
+	us.utxoMap[utxoJ.InputID()].index = i
 	delete(us.utxoMap, utxoI.InputID())
+	us.byAge.Remove(entry.age)
 
 	return utxoI
 }
 
+// RemoveAll removes the UTXOs with the provided ids from this set. Rather than
+// swapping each removed UTXO with the last one, the list of UTXOs is compacted
+// once after all the removals.
+func (us *UTXOSet) RemoveAll(utxoIDs []ids.ID) {
+	removed := 0
+	for _, utxoID := range utxoIDs {
+		entry, ok := us.utxoMap[utxoID]
+		if !ok {
+			continue
+		}
+		delete(us.utxoMap, utxoID)
+		us.byAge.Remove(entry.age)
+		us.UTXOs[entry.index] = nil
+		removed++
+	}
+	if removed == 0 {
+		return
+	}
+
+	j := 0
+	for i, utxo := range us.UTXOs {
+		if utxo == nil {
+			continue
+		}
+		if i != j {
+			us.UTXOs[j] = utxo
+			us.utxoMap[utxo.InputID()].index = j
+		}
+		j++
+	}
+	for i := j; i < len(us.UTXOs); i++ {
+		us.UTXOs[i] = nil // Allow the removed UTXOs to be garbage collected
+	}
+	us.UTXOs = us.UTXOs[:j]
+}
+
+// TotalAmounts returns the total amount held in this set for each asset.
+// UTXOs whose outputs aren't transferable aren't counted. An error is returned
+// if the total of any asset overflows.
+func (us *UTXOSet) TotalAmounts() (map[ids.ID]uint64, error) {
+	amounts := make(map[ids.ID]uint64)
+	var err error
+	us.ForEach(func(utxo *avax.UTXO) bool {
+		out, ok := utxo.Out.(avax.TransferableOut)
+		if !ok {
+			return true
+		}
+		assetID := utxo.AssetID()
+		amount, addErr := math.Add64(amounts[assetID], out.Amount())
+		if addErr != nil {
+			err = fmt.Errorf("total amount of asset %s overflows", assetID)
+			return false
+		}
+		amounts[assetID] = amount
+		return true
+	})
+	return amounts, err
+}
+
+// ForEach calls [fn] on each UTXO in this set, stopping early if [fn] returns
+// false. The set must not be modified by [fn].
+func (us *UTXOSet) ForEach(fn func(*avax.UTXO) bool) {
+	for _, utxo := range us.UTXOs {
+		if !fn(utxo) {
+			return
+		}
+	}
+}
+
+// ForEachOldestFirst calls [fn] on each UTXO in this set in the order they
+// were added, stopping early if [fn] returns false. The set must not be
+// modified by [fn].
+func (us *UTXOSet) ForEachOldestFirst(fn func(*avax.UTXO) bool) {
+	for e := us.byAge.Front(); e != nil; e = e.Next() {
+		if !fn(e.Value.(*avax.UTXO)) {
+			return
+		}
+	}
+}
+
 // PrefixedString returns a string with each new line prefixed with [prefix]
 func (us *UTXOSet) PrefixedString(prefix string) string {
 	s := strings.Builder{}
 
 	s.WriteString(fmt.Sprintf("UTXOs (length=%d):", len(us.UTXOs)))
-	for i, utxo := range us.UTXOs {
+	i := 0
+	us.ForEach(func(utxo *avax.UTXO) bool {
 		utxoID := utxo.InputID()
 		txID, txIndex := utxo.InputSource()
 
@@ -83,7 +187,9 @@ func (us *UTXOSet) PrefixedString(prefix string) string {
 			prefix, utxoID,
 			prefix, txID,
 			prefix, txIndex))
-	}
+		i++
+		return true
+	})
 
 	return s.String()
 }
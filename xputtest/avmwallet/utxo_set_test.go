@@ -0,0 +1,187 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avmwallet
+
+import (
+	"testing"
+
+	stdmath "math"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+func TestUTXOSetRemoveAll(t *testing.T) {
+	us := &UTXOSet{}
+
+	utxos := []*avax.UTXO{}
+	for i := uint64(0); i < 5; i++ {
+		utxo := &avax.UTXO{
+			UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(i)},
+			Asset:  avax.Asset{ID: ids.Empty.Prefix(100)},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 1000,
+			},
+		}
+		us.Put(utxo)
+		utxos = append(utxos, utxo)
+	}
+
+	us.RemoveAll([]ids.ID{
+		utxos[0].InputID(),
+		utxos[2].InputID(),
+		utxos[2].InputID(),
+		ids.Empty.Prefix(200),
+	})
+
+	if len(us.UTXOs) != 3 {
+		t.Fatalf("expected 3 UTXOs, found %d", len(us.UTXOs))
+	}
+	if len(us.utxoMap) != len(us.UTXOs) {
+		t.Fatalf("UTXO map has length %d but UTXO list has length %d", len(us.utxoMap), len(us.UTXOs))
+	}
+	for _, i := range []int{0, 2} {
+		if utxo := us.Get(utxos[i].InputID()); utxo != nil {
+			t.Fatalf("UTXO %d should have been removed", i)
+		}
+	}
+	for _, i := range []int{1, 3, 4} {
+		if utxo := us.Get(utxos[i].InputID()); utxo != utxos[i] {
+			t.Fatalf("UTXO %d should have been kept", i)
+		}
+	}
+}
+
+func TestUTXOSetForEach(t *testing.T) {
+	us := &UTXOSet{}
+	for i := uint64(0); i < 5; i++ {
+		us.Put(&avax.UTXO{
+			UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(i)},
+			Asset:  avax.Asset{ID: ids.Empty.Prefix(100)},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 1000,
+			},
+		})
+	}
+
+	visited := 0
+	us.ForEach(func(*avax.UTXO) bool {
+		visited++
+		return true
+	})
+	if visited != 5 {
+		t.Fatalf("expected to visit 5 UTXOs, visited %d", visited)
+	}
+
+	visited = 0
+	us.ForEach(func(*avax.UTXO) bool {
+		visited++
+		return visited < 2
+	})
+	if visited != 2 {
+		t.Fatalf("expected to stop after visiting 2 UTXOs, visited %d", visited)
+	}
+}
+
+func TestUTXOSetForEachOldestFirst(t *testing.T) {
+	us := &UTXOSet{}
+
+	utxos := []*avax.UTXO{}
+	for i := uint64(0); i < 5; i++ {
+		utxo := &avax.UTXO{
+			UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(i)},
+			Asset:  avax.Asset{ID: ids.Empty.Prefix(100)},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 1000,
+			},
+		}
+		us.Put(utxo)
+		utxos = append(utxos, utxo)
+	}
+
+	// Removing the first UTXO moves the newest UTXO to the front of the list
+	us.Remove(utxos[0].InputID())
+
+	visited := []*avax.UTXO{}
+	us.ForEachOldestFirst(func(utxo *avax.UTXO) bool {
+		visited = append(visited, utxo)
+		return len(visited) < 3
+	})
+	if len(visited) != 3 {
+		t.Fatalf("expected to stop after visiting 3 UTXOs, visited %d", len(visited))
+	}
+	for i, utxo := range visited {
+		if utxo != utxos[i+1] {
+			t.Fatalf("expected UTXO %d to be visited in position %d", i+1, i)
+		}
+	}
+
+	us.RemoveAll([]ids.ID{utxos[2].InputID()})
+
+	visited = visited[:0]
+	us.ForEachOldestFirst(func(utxo *avax.UTXO) bool {
+		visited = append(visited, utxo)
+		return true
+	})
+	if len(visited) != 3 {
+		t.Fatalf("expected to visit 3 UTXOs, visited %d", len(visited))
+	}
+	for i, j := range []int{1, 3, 4} {
+		if visited[i] != utxos[j] {
+			t.Fatalf("expected UTXO %d to be visited in position %d", j, i)
+		}
+	}
+}
+
+func TestUTXOSetTotalAmounts(t *testing.T) {
+	us := &UTXOSet{}
+
+	assetA := ids.Empty.Prefix(100)
+	assetB := ids.Empty.Prefix(101)
+	assetC := ids.Empty.Prefix(102)
+	us.Put(&avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(0)},
+		Asset:  avax.Asset{ID: assetA},
+		Out:    &secp256k1fx.TransferOutput{Amt: 1000},
+	})
+	us.Put(&avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(1)},
+		Asset:  avax.Asset{ID: assetA},
+		Out:    &secp256k1fx.TransferOutput{Amt: 500},
+	})
+	us.Put(&avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(2)},
+		Asset:  avax.Asset{ID: assetB},
+		Out:    &secp256k1fx.TransferOutput{Amt: 7},
+	})
+	us.Put(&avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(3)},
+		Asset:  avax.Asset{ID: assetC},
+		Out:    &secp256k1fx.MintOutput{},
+	})
+
+	amounts, err := us.TotalAmounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(amounts) != 2 {
+		t.Fatalf("expected amounts for 2 assets, found %d", len(amounts))
+	}
+	if amount := amounts[assetA]; amount != 1500 {
+		t.Fatalf("expected 1500 of asset A, found %d", amount)
+	}
+	if amount := amounts[assetB]; amount != 7 {
+		t.Fatalf("expected 7 of asset B, found %d", amount)
+	}
+
+	us.Put(&avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(4)},
+		Asset:  avax.Asset{ID: assetB},
+		Out:    &secp256k1fx.TransferOutput{Amt: stdmath.MaxUint64},
+	})
+	if _, err := us.TotalAmounts(); err == nil {
+		t.Fatalf("should have failed due to the total of asset B overflowing")
+	}
+}
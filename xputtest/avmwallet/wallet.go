@@ -4,13 +4,13 @@
 package avmwallet
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
 	stdmath "math"
 
 	"github.com/ava-labs/avalanchego/ids"
-	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/avalanchego/utils/codec"
 	"github.com/ava-labs/avalanchego/utils/crypto"
 	"github.com/ava-labs/avalanchego/utils/logging"
@@ -26,6 +26,53 @@ const (
 	codecVersion = 0
 )
 
+var (
+	// ErrNoKeys is returned when a tx is requested from a wallet without any
+	// keys
+	ErrNoKeys = errors.New("no keys")
+
+	// ErrNoAssets is returned when txs are requested without any assets to
+	// send
+	ErrNoAssets = errors.New("no assets to generate transactions for")
+
+	// ErrNoPayments is returned when a tx is requested without any payments
+	ErrNoPayments = errors.New("no payments")
+
+	// ErrAmountZero is returned when a payment of zero funds is requested
+	ErrAmountZero = errors.New("invalid amount")
+
+	// ErrOverflow is returned when the requested or spent funds overflow
+	ErrOverflow = errors.New("amount overflow")
+
+	// ErrInsufficientFunds is returned when this wallet can't spend enough
+	// funds to cover the requested payments
+	ErrInsufficientFunds = errors.New("insufficient funds")
+
+	// ErrInsufficientInputs is returned when this wallet doesn't hold enough
+	// spendable UTXOs to create a tx with the minimum number of inputs
+	ErrInsufficientInputs = errors.New("insufficient inputs")
+
+	// ErrDuplicateInput is returned when a tx is requested that consumes the
+	// same UTXO more than once
+	ErrDuplicateInput = errors.New("UTXO consumed multiple times")
+
+	// ErrUnknownUTXO is returned when a tx is requested that consumes a UTXO
+	// this wallet doesn't hold
+	ErrUnknownUTXO = errors.New("UTXO not held by this wallet")
+
+	// ErrWrongAsset is returned when a tx is requested that consumes a UTXO of
+	// a different asset than the one being sent
+	ErrWrongAsset = errors.New("UTXO has the wrong asset")
+
+	// ErrMinChangeTooHigh is returned when a tx is requested that pays less
+	// than the minimum change
+	ErrMinChangeTooHigh = errors.New("minimum change exceeds the payment amount")
+
+	// ErrInvalidChangeOwners is returned when change is requested to be sent to
+	// owners that this wallet can't spend from
+	ErrInvalidChangeOwners = errors.New("invalid change owners")
+)
+
 // Wallet is a holder for keys and UTXOs for the Avalanche DAG.
 type Wallet struct {
 	networkID uint32
@@ -36,11 +83,30 @@ type Wallet struct {
 	log   logging.Logger
 
 	keychain *secp256k1fx.Keychain // Mapping from public address to the SigningKeys
+	created  ids.ShortSet          // Addresses whose keys were generated by this wallet
 	utxoSet  *UTXOSet              // Mapping from utxoIDs to UTXOs
 
 	balance map[ids.ID]uint64
 	txFee   uint64
 
+	// If true, the oldest UTXOs are spent first rather than in arbitrary order
+	spendOldestFirst bool
+
+	// The minimum number of inputs each tx created by CreateMultiTx consumes
+	minInputs int
+
+	// The number of outputs the change of each tx is split across
+	changeSplits int
+
+	// The owners of change outputs. If nil, change is sent to a single address
+	// of this wallet.
+	changeOwners *secp256k1fx.OutputOwners
+
+	// Change below this amount is burned rather than returned to this wallet
+	minChange uint64
+	// The total amount of change burned by the txs this wallet created
+	burned uint64
+
 	txs []*avm.Tx
 }
 
@@ -91,12 +157,33 @@ func (w *Wallet) GetAddress() (ids.ShortID, error) {
 // so the address can be used later
 func (w *Wallet) CreateAddress() (ids.ShortID, error) {
 	privKey, err := w.keychain.New()
-	return privKey.PublicKey().Address(), err
+	if err != nil {
+		return ids.ShortID{}, err
+	}
+	addr := privKey.PublicKey().Address()
+	w.created.Add(addr)
+	return addr, nil
 }
 
 // ImportKey imports a private key into this wallet
 func (w *Wallet) ImportKey(sk *crypto.PrivateKeySECP256K1R) { w.keychain.Add(sk) }
 
+// ExportKeys returns the private keys this wallet manages. If [createdOnly],
+// only the keys generated by this wallet are returned, excluding any imported
+// keys.
+// The returned keys control this wallet's funds, so they should be handled
+// with the same care as any other private key.
+func (w *Wallet) ExportKeys(createdOnly bool) []*crypto.PrivateKeySECP256K1R {
+	keys := make([]*crypto.PrivateKeySECP256K1R, 0, len(w.keychain.Keys))
+	for _, key := range w.keychain.Keys {
+		if createdOnly && !w.created.Contains(key.PublicKey().Address()) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
 // AddUTXO adds a new UTXO to this wallet if this wallet may spend it
 // The UTXO's output must be an OutputPayment
 func (w *Wallet) AddUTXO(utxo *avax.UTXO) {
@@ -106,11 +193,24 @@ func (w *Wallet) AddUTXO(utxo *avax.UTXO) {
 	}
 
 	if _, _, err := w.keychain.Spend(out, stdmath.MaxUint64); err == nil {
-		w.utxoSet.Put(utxo)
-		w.balance[utxo.AssetID()] += out.Amount()
+		w.AddOwnedUTXO(utxo)
 	}
 }
 
+// AddOwnedUTXO adds a new UTXO to this wallet without checking whether this
+// wallet may spend it. It should only be used for UTXOs known to be spendable
+// by this wallet, such as the outputs this wallet created for itself.
+// The UTXO's output must be an OutputPayment
+func (w *Wallet) AddOwnedUTXO(utxo *avax.UTXO) {
+	out, ok := utxo.Out.(avax.TransferableOut)
+	if !ok {
+		return
+	}
+
+	w.utxoSet.Put(utxo)
+	w.balance[utxo.AssetID()] += out.Amount()
+}
+
 // RemoveUTXO from this wallet
 func (w *Wallet) RemoveUTXO(utxoID ids.ID) {
 	utxo := w.utxoSet.Get(utxoID)
@@ -129,88 +229,320 @@ func (w *Wallet) RemoveUTXO(utxoID ids.ID) {
 	w.utxoSet.Remove(utxoID)
 }
 
+// checkInvariants returns an error if the UTXO set and the balances tracked by
+// this wallet are inconsistent with each other
+func (w *Wallet) checkInvariants() error {
+	us := w.utxoSet
+	if numUTXOs, numIndexed := len(us.UTXOs), len(us.utxoMap); numUTXOs != numIndexed {
+		return fmt.Errorf("UTXO set contains %d UTXOs but indexes %d", numUTXOs, numIndexed)
+	}
+	for utxoID, entry := range us.utxoMap {
+		if i := entry.index; i < 0 || i >= len(us.UTXOs) || us.UTXOs[i].InputID() != utxoID {
+			return fmt.Errorf("UTXO %s is indexed at the wrong position %d", utxoID, i)
+		}
+		if _, ok := us.UTXOs[entry.index].Out.(avax.TransferableOut); !ok {
+			return fmt.Errorf("UTXO %s has an unspendable output", utxoID)
+		}
+	}
+
+	if numUTXOs, numAged := len(us.UTXOs), us.byAge.Len(); numUTXOs != numAged {
+		return fmt.Errorf("UTXO set contains %d UTXOs but orders %d by age", numUTXOs, numAged)
+	}
+	for e := us.byAge.Front(); e != nil; e = e.Next() {
+		utxoID := e.Value.(*avax.UTXO).InputID()
+		entry, ok := us.utxoMap[utxoID]
+		if !ok || entry.age != e {
+			return fmt.Errorf("UTXO %s is ordered by age but not indexed", utxoID)
+		}
+		if prev := e.Prev(); prev != nil && us.utxoMap[prev.Value.(*avax.UTXO).InputID()].seq >= entry.seq {
+			return fmt.Errorf("UTXO %s is ordered after a newer UTXO", utxoID)
+		}
+	}
+
+	balances, err := us.TotalAmounts()
+	if err != nil {
+		return err
+	}
+
+	if numAssets, numTracked := len(balances), len(w.balance); numAssets != numTracked {
+		return fmt.Errorf("UTXO set holds %d assets but balances are tracked for %d", numAssets, numTracked)
+	}
+	for assetID, balance := range balances {
+		if tracked := w.balance[assetID]; tracked != balance {
+			return fmt.Errorf("UTXO set holds %d of asset %s but the tracked balance is %d", balance, assetID, tracked)
+		}
+	}
+	return nil
+}
+
+// SetSpendOldestFirst sets whether the UTXOs that have been held by this wallet
+// the longest are spent before newer UTXOs
+func (w *Wallet) SetSpendOldestFirst(oldestFirst bool) { w.spendOldestFirst = oldestFirst }
+
+// SetMinInputs sets the minimum number of inputs each tx created by
+// CreateMultiTx consumes. Each input requires its own signature, so this
+// increases the verification cost of each tx.
+func (w *Wallet) SetMinInputs(minInputs int) { w.minInputs = minInputs }
+
+// SetChangeSplits sets the number of outputs the change of each tx is split
+// across. Splitting the change grows the number of UTXOs held by this wallet.
+func (w *Wallet) SetChangeSplits(changeSplits int) { w.changeSplits = changeSplits }
+
+// SetChangeOwners sets the owners of the change outputs of the txs this wallet
+// creates. If [owners] is nil, change is sent to a single address of this
+// wallet. This wallet must hold enough of the owners' keys to spend the change
+// now, otherwise ErrInvalidChangeOwners is returned.
+func (w *Wallet) SetChangeOwners(owners *secp256k1fx.OutputOwners) error {
+	if owners == nil {
+		w.changeOwners = nil
+		return nil
+	}
+	if err := owners.Verify(); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidChangeOwners, err)
+	}
+	if owners.Threshold == 0 {
+		return fmt.Errorf("%w: change with a threshold of 0 is spendable by anyone", ErrInvalidChangeOwners)
+	}
+	now := w.clock.Unix()
+	if owners.Locktime > now {
+		return fmt.Errorf("%w: change would be locked until %d", ErrInvalidChangeOwners, owners.Locktime)
+	}
+
+	changeOwners := &secp256k1fx.OutputOwners{
+		Locktime:  owners.Locktime,
+		Threshold: owners.Threshold,
+		Addrs:     append([]ids.ShortID(nil), owners.Addrs...),
+	}
+	probe := &secp256k1fx.TransferOutput{
+		Amt:          1,
+		OutputOwners: *changeOwners,
+	}
+	if _, _, err := w.keychain.Spend(probe, now); err != nil {
+		return fmt.Errorf("%w: change wouldn't be spendable by this wallet: %s", ErrInvalidChangeOwners, err)
+	}
+	w.changeOwners = changeOwners
+	return nil
+}
+
+// SetMinChange sets the minimum amount of each change output. When possible,
+// another UTXO is spent to raise the change above this amount. Otherwise, the
+// change is burned rather than creating a dust UTXO. If the change can't be
+// split into outputs of at least this amount, fewer outputs are created.
+// Creating a tx that pays less than this amount fails with ErrMinChangeTooHigh,
+// as its payment would be dust.
+func (w *Wallet) SetMinChange(minChange uint64) { w.minChange = minChange }
+
+// Burned returns the total amount of change burned by the txs this wallet
+// created to avoid creating dust UTXOs
+func (w *Wallet) Burned() uint64 { return w.burned }
+
+// isDust returns true if [change] is too small to be returned to this wallet
+func (w *Wallet) isDust(change uint64) bool { return change > 0 && change < w.minChange }
+
 // Balance returns the amount of the assets in this wallet
 func (w *Wallet) Balance(assetID ids.ID) uint64 { return w.balance[assetID] }
 
+// Payment is an amount of funds to send to an address
+type Payment struct {
+	Addr   ids.ShortID
+	Amount uint64
+}
+
 // CreateTx returns a tx that sends [amount] of [assetID] to [destAddr]
 func (w *Wallet) CreateTx(assetID ids.ID, amount uint64, destAddr ids.ShortID) (*avm.Tx, error) {
-	if amount == 0 {
-		return nil, errors.New("invalid amount")
+	return w.CreateMultiTx(assetID, []Payment{{
+		Addr:   destAddr,
+		Amount: amount,
+	}})
+}
+
+// CreateMultiTx returns a tx that sends each of [payments] in [assetID]. Any
+// remaining funds consumed by the tx are returned to this wallet as change.
+// If the change would be below the minimum change, more UTXOs are consumed to
+// raise it. If no UTXOs remain, the change is burned.
+func (w *Wallet) CreateMultiTx(assetID ids.ID, payments []Payment) (*avm.Tx, error) {
+	if w.keychain.Addrs.Len() == 0 {
+		return nil, ErrNoKeys
+	}
+	amount, err := paymentsAmount(payments)
+	if err != nil {
+		return nil, err
+	}
+	if amount < w.minChange {
+		return nil, ErrMinChangeTooHigh
 	}
 
 	amountSpent := uint64(0)
 	time := w.clock.Unix()
 
+	forEach := w.utxoSet.ForEach
+	if w.spendOldestFirst {
+		forEach = w.utxoSet.ForEachOldestFirst
+	}
+
 	ins := []*avax.TransferableInput{}
 	keys := [][]*crypto.PrivateKeySECP256K1R{}
-	for _, utxo := range w.utxoSet.UTXOs {
+	var spendErr error
+	forEach(func(utxo *avax.UTXO) bool {
 		if utxo.AssetID() != assetID {
-			continue
+			return true
 		}
-		inputIntf, signers, err := w.keychain.Spend(utxo.Out, time)
+		in, signers, err := w.spend(utxo, time)
 		if err != nil {
-			continue
-		}
-		input, ok := inputIntf.(avax.TransferableIn)
-		if !ok {
-			continue
+			return true
 		}
-		spent, err := math.Add64(amountSpent, input.Amount())
+		spent, err := math.Add64(amountSpent, in.Input().Amount())
 		if err != nil {
-			return nil, err
+			spendErr = ErrOverflow
+			return false
 		}
 		amountSpent = spent
 
-		in := &avax.TransferableInput{
-			UTXOID: utxo.UTXOID,
-			Asset:  avax.Asset{ID: assetID},
-			In:     input,
-		}
-
 		ins = append(ins, in)
 		keys = append(keys, signers)
 
-		if amountSpent >= amount {
-			break
-		}
+		return amountSpent < amount || len(ins) < w.minInputs || w.isDust(amountSpent-amount)
+	})
+	if spendErr != nil {
+		return nil, spendErr
 	}
 
 	if amountSpent < amount {
-		return nil, errors.New("insufficient funds")
+		return nil, ErrInsufficientFunds
 	}
+	if len(ins) < w.minInputs {
+		return nil, ErrInsufficientInputs
+	}
+	return w.createTx(assetID, ins, keys, amountSpent-amount, payments)
+}
 
-	avax.SortTransferableInputsWithSigners(ins, keys)
+// CreateTxFromInputs returns a tx that consumes exactly the UTXOs referenced by
+// [inputs] to send each of [payments] in [assetID]. Every input must be a UTXO
+// of [assetID] held and spendable by this wallet. Any remaining funds consumed
+// by the tx are returned to this wallet as change, unless the change is below
+// the minimum change, in which case it is burned.
+func (w *Wallet) CreateTxFromInputs(assetID ids.ID, inputs []avax.UTXOID, payments []Payment) (*avm.Tx, error) {
+	if w.keychain.Addrs.Len() == 0 {
+		return nil, ErrNoKeys
+	}
+	amount, err := paymentsAmount(payments)
+	if err != nil {
+		return nil, err
+	}
+	if amount < w.minChange {
+		return nil, ErrMinChangeTooHigh
+	}
 
-	outs := []*avax.TransferableOutput{{
-		Asset: avax.Asset{ID: assetID},
-		Out: &secp256k1fx.TransferOutput{
-			Amt: amount,
-			OutputOwners: secp256k1fx.OutputOwners{
-				Locktime:  0,
-				Threshold: 1,
-				Addrs:     []ids.ShortID{destAddr},
-			},
-		},
-	}}
+	amountSpent := uint64(0)
+	time := w.clock.Unix()
 
-	if amountSpent > amount {
-		changeAddr, err := w.GetAddress()
+	ins := make([]*avax.TransferableInput, len(inputs))
+	keys := make([][]*crypto.PrivateKeySECP256K1R, len(inputs))
+	consumed := ids.Set{}
+	for i, input := range inputs {
+		utxoID := input.InputID()
+		if consumed.Contains(utxoID) {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateInput, utxoID)
+		}
+		consumed.Add(utxoID)
+
+		utxo := w.utxoSet.Get(utxoID)
+		if utxo == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownUTXO, utxoID)
+		}
+		if utxoAssetID := utxo.AssetID(); utxoAssetID != assetID {
+			return nil, fmt.Errorf("%w: UTXO %s has asset %s but expected %s", ErrWrongAsset, utxoID, utxoAssetID, assetID)
+		}
+		in, signers, err := w.spend(utxo, time)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't spend UTXO %s: %w", utxoID, err)
+		}
+		spent, err := math.Add64(amountSpent, in.Input().Amount())
 		if err != nil {
-			return nil, err
+			return nil, ErrOverflow
 		}
+		amountSpent = spent
+
+		ins[i] = in
+		keys[i] = signers
+	}
+
+	if amountSpent < amount {
+		return nil, ErrInsufficientFunds
+	}
+	return w.createTx(assetID, ins, keys, amountSpent-amount, payments)
+}
+
+// paymentsAmount returns the total amount sent by [payments]
+func paymentsAmount(payments []Payment) (uint64, error) {
+	if len(payments) == 0 {
+		return 0, ErrNoPayments
+	}
+
+	amount := uint64(0)
+	for _, payment := range payments {
+		if payment.Amount == 0 {
+			return 0, ErrAmountZero
+		}
+		newAmount, err := math.Add64(amount, payment.Amount)
+		if err != nil {
+			return 0, ErrOverflow
+		}
+		amount = newAmount
+	}
+	return amount, nil
+}
+
+// spend returns an input that consumes [utxo] along with the keys needed to
+// sign it
+func (w *Wallet) spend(utxo *avax.UTXO, time uint64) (*avax.TransferableInput, []*crypto.PrivateKeySECP256K1R, error) {
+	inputIntf, signers, err := w.keychain.Spend(utxo.Out, time)
+	if err != nil {
+		return nil, nil, err
+	}
+	input, ok := inputIntf.(avax.TransferableIn)
+	if !ok {
+		return nil, nil, errors.New("input is not transferable")
+	}
+	return &avax.TransferableInput{
+		UTXOID: utxo.UTXOID,
+		Asset:  avax.Asset{ID: utxo.AssetID()},
+		In:     input,
+	}, signers, nil
+}
+
+// createTx returns a signed tx that consumes [ins] to send each of [payments]
+// and returns [change] to this wallet
+func (w *Wallet) createTx(
+	assetID ids.ID,
+	ins []*avax.TransferableInput,
+	keys [][]*crypto.PrivateKeySECP256K1R,
+	change uint64,
+	payments []Payment,
+) (*avm.Tx, error) {
+	avax.SortTransferableInputsWithSigners(ins, keys)
+
+	outs := make([]*avax.TransferableOutput, 0, len(payments)+1)
+	for _, payment := range payments {
 		outs = append(outs, &avax.TransferableOutput{
 			Asset: avax.Asset{ID: assetID},
 			Out: &secp256k1fx.TransferOutput{
-				Amt: amountSpent - amount,
+				Amt: payment.Amount,
 				OutputOwners: secp256k1fx.OutputOwners{
 					Locktime:  0,
 					Threshold: 1,
-					Addrs:     []ids.ShortID{changeAddr},
+					Addrs:     []ids.ShortID{payment.Addr},
 				},
 			},
 		})
 	}
 
+	changeOuts, burned, err := w.changeOutputs(assetID, change)
+	if err != nil {
+		return nil, err
+	}
+	outs = append(outs, changeOuts...)
+
 	avax.SortTransferableOutputs(outs, w.codec)
 
 	tx := &avm.Tx{UnsignedTx: &avm.BaseTx{BaseTx: avax.BaseTx{
@@ -219,41 +551,151 @@ func (w *Wallet) CreateTx(assetID ids.ID, amount uint64, destAddr ids.ShortID) (
 		Outs:         outs,
 		Ins:          ins,
 	}}}
-	return tx, tx.SignSECP256K1Fx(w.codec, keys)
+	if err := tx.SignSECP256K1Fx(w.codec, keys); err != nil {
+		return nil, err
+	}
+	w.burned += burned
+	return tx, nil
+}
+
+// changeOutputs returns the outputs that return [change] of [assetID] to this
+// wallet, along with the amount of [change] that is burned instead
+func (w *Wallet) changeOutputs(assetID ids.ID, change uint64) ([]*avax.TransferableOutput, uint64, error) {
+	if change == 0 {
+		return nil, 0, nil
+	}
+
+	// Each output must hold at least the minimum change, so if the change is
+	// too small to be split across every output, fewer outputs are created
+	minAmount := w.minChange
+	if minAmount == 0 {
+		minAmount = 1
+	}
+	numOuts := uint64(1)
+	if w.changeSplits > 1 {
+		numOuts = uint64(w.changeSplits)
+	}
+	if maxOuts := change / minAmount; maxOuts < numOuts {
+		numOuts = maxOuts
+	}
+	if numOuts == 0 {
+		return nil, change, nil
+	}
+
+	owners := w.changeOwners
+	if owners == nil {
+		changeAddr, err := w.GetAddress()
+		if err != nil {
+			return nil, 0, err
+		}
+		owners = &secp256k1fx.OutputOwners{
+			Locktime:  0,
+			Threshold: 1,
+			Addrs:     []ids.ShortID{changeAddr},
+		}
+	}
+
+	// Any remainder of the split is added to the first output
+	amount := change / numOuts
+	remainder := change % numOuts
+	outs := make([]*avax.TransferableOutput, numOuts)
+	for i := range outs {
+		outs[i] = &avax.TransferableOutput{
+			Asset: avax.Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: amount + remainder,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Locktime:  owners.Locktime,
+					Threshold: owners.Threshold,
+					Addrs:     append([]ids.ShortID(nil), owners.Addrs...),
+				},
+			},
+		}
+		remainder = 0
+	}
+	return outs, 0, nil
 }
 
 // GenerateTxs generates the transactions that will be sent
 // during the test
 // Generate them all on test initialization so tx generation is not bottleneck
 // in testing
-func (w *Wallet) GenerateTxs(numTxs int, assetID ids.ID) error {
-	w.log.Info("Generating %d transactions", numTxs)
+// Successive transactions send the next asset in [assetIDs] that this wallet
+// can still spend, so the load is spread across the assets. The number of
+// transactions generated for each asset is returned.
+// If [ctx] is cancelled, generation stops early and the transactions generated
+// so far are kept.
+func (w *Wallet) GenerateTxs(ctx context.Context, numTxs int, assetIDs []ids.ID) (map[ids.ID]int, error) {
+	if len(assetIDs) == 0 {
+		return nil, ErrNoAssets
+	}
 
-	ctx := snow.DefaultContextTest()
-	ctx.NetworkID = w.networkID
-	ctx.ChainID = w.chainID
+	w.log.Info("Generating %d transactions", numTxs)
 
 	frequency := numTxs / 50
 	if frequency > 1000 {
 		frequency = 1000
 	}
+	if frequency < 1 {
+		frequency = 1
+	}
+
+	numTxsPerAsset := make(map[ids.ID]int, len(assetIDs))
+	dry := make([]bool, len(assetIDs)) // true if that asset can't be spent
+	numDry := 0
+	next := 0
 
 	w.txs = make([]*avm.Tx, numTxs)
 	for i := 0; i < numTxs; i++ {
+		if i%frequency == 0 {
+			if err := ctx.Err(); err != nil {
+				w.log.Info("Stopped generating transactions after %d out of %d", i, numTxs)
+				w.txs = w.txs[:i]
+				return numTxsPerAsset, err
+			}
+		}
+
 		addr, err := w.CreateAddress()
 		if err != nil {
-			return err
+			return numTxsPerAsset, err
 		}
-		tx, err := w.CreateTx(assetID, 1, addr)
-		if err != nil {
-			return err
+
+		var (
+			tx      *avm.Tx
+			assetID ids.ID
+		)
+		for tx == nil {
+			if numDry == len(assetIDs) {
+				w.log.Info("Ran out of funds after generating %d out of %d transactions", i, numTxs)
+				w.txs = w.txs[:i]
+				return numTxsPerAsset, ErrInsufficientFunds
+			}
+
+			assetIndex := next
+			next = (next + 1) % len(assetIDs)
+			if dry[assetIndex] {
+				continue
+			}
+
+			assetID = assetIDs[assetIndex]
+			tx, err = w.CreateTx(assetID, 1, addr)
+			switch {
+			case err == ErrInsufficientFunds, err == ErrInsufficientInputs:
+				w.log.Info("Asset %s ran out of spendable UTXOs after %d transactions", assetID, numTxsPerAsset[assetID])
+				dry[assetIndex] = true
+				numDry++
+			case err != nil:
+				return numTxsPerAsset, err
+			}
 		}
+		numTxsPerAsset[assetID]++
 
 		for _, utxoID := range tx.InputUTXOs() {
 			w.RemoveUTXO(utxoID.InputID())
 		}
+		// Every output of the tx is sent to an address of this wallet
 		for _, utxo := range tx.UTXOs() {
-			w.AddUTXO(utxo)
+			w.AddOwnedUTXO(utxo)
 		}
 
 		if numGenerated := i + 1; numGenerated%frequency == 0 {
@@ -264,7 +706,7 @@ func (w *Wallet) GenerateTxs(numTxs int, assetID ids.ID) error {
 	}
 
 	w.log.Info("Finished generating %d transactions", numTxs)
-	return nil
+	return numTxsPerAsset, nil
 }
 
 // NextTx returns the next tx to be sent as part of xput test
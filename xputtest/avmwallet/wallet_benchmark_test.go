@@ -0,0 +1,70 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avmwallet
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+const numBenchmarkUTXOs = 10000
+
+// benchmarkUTXOs returns a wallet along with [numBenchmarkUTXOs] UTXOs that are
+// spendable by it
+func benchmarkUTXOs(b *testing.B) (*Wallet, []*avax.UTXO) {
+	chainID := ids.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	w, err := NewWallet(logging.NoLog{}, 12345, chainID, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	addr, err := w.GetAddress()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	utxos := make([]*avax.UTXO, numBenchmarkUTXOs)
+	for i := range utxos {
+		utxos[i] = &avax.UTXO{
+			UTXOID: avax.UTXOID{
+				TxID:        ids.Empty.Prefix(uint64(i)),
+				OutputIndex: 0,
+			},
+			Asset: avax.Asset{ID: ids.Empty.Prefix(1)},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 1000,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{addr},
+				},
+			},
+		}
+	}
+	return w, utxos
+}
+
+func BenchmarkWalletAddUTXO(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		w, utxos := benchmarkUTXOs(b)
+		b.StartTimer()
+		for _, utxo := range utxos {
+			w.AddUTXO(utxo)
+		}
+	}
+}
+
+func BenchmarkWalletAddOwnedUTXO(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		w, utxos := benchmarkUTXOs(b)
+		b.StartTimer()
+		for _, utxo := range utxos {
+			w.AddOwnedUTXO(utxo)
+		}
+	}
+}
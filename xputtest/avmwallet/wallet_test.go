@@ -4,8 +4,12 @@
 package avmwallet
 
 import (
+	"context"
+	"errors"
 	"testing"
 
+	stdmath "math"
+
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/crypto"
 	"github.com/ava-labs/avalanchego/utils/logging"
@@ -113,6 +117,38 @@ func TestWalletAddInvalidUTXO(t *testing.T) {
 	}
 }
 
+func TestWalletAddOwnedUTXO(t *testing.T) {
+	chainID := ids.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	w, err := NewWallet(logging.NoLog{}, 12345, chainID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	utxo := &avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(0)},
+		Asset:  avax.Asset{ID: ids.Empty.Prefix(1)},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: 1000,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{ids.NewShortID([20]byte{1})},
+			},
+		},
+	}
+
+	// The wallet doesn't hold the key of the UTXO's owner, so AddUTXO should
+	// ignore it
+	w.AddUTXO(utxo)
+	if balance := w.Balance(utxo.AssetID()); balance != 0 {
+		t.Fatalf("expected balance to be 0, was %d", balance)
+	}
+
+	w.AddOwnedUTXO(utxo)
+	if balance := w.Balance(utxo.AssetID()); balance != 1000 {
+		t.Fatalf("expected balance to be 1000, was %d", balance)
+	}
+}
+
 func TestWalletCreateTx(t *testing.T) {
 	chainID := ids.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
 	w, err := NewWallet(logging.NoLog{}, 12345, chainID, 0)
@@ -216,3 +252,888 @@ func TestWalletString(t *testing.T) {
 		t.Fatalf("got:\n%s\n\nexpected:\n%s", str, expected)
 	}
 }
+
+// cancelAfterContext reports itself as cancelled once Err has been called
+// [checks] times
+type cancelAfterContext struct {
+	context.Context
+	checks int
+}
+
+func (ctx *cancelAfterContext) Err() error {
+	if ctx.checks <= 0 {
+		return context.Canceled
+	}
+	ctx.checks--
+	return nil
+}
+
+func TestWalletGenerateTxs(t *testing.T) {
+	chainID := ids.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	w, err := NewWallet(logging.NoLog{}, 12345, chainID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assetID := ids.Empty.Prefix(0)
+
+	addr, err := w.GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.AddUTXO(&avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(1)},
+		Asset:  avax.Asset{ID: assetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: 1000,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{addr},
+			},
+		},
+	})
+
+	if _, err := w.GenerateTxs(context.Background(), 10, []ids.ID{assetID}); err != nil {
+		t.Fatal(err)
+	}
+
+	numTxs := 0
+	for tx := w.NextTx(); tx != nil; tx = w.NextTx() {
+		numTxs++
+	}
+	if numTxs != 10 {
+		t.Fatalf("expected 10 txs, generated %d", numTxs)
+	}
+	if balance := w.Balance(assetID); balance != 1000 {
+		t.Fatalf("expected balance to be 1000, was %d", balance)
+	}
+}
+
+func TestWalletGenerateTxsCancelled(t *testing.T) {
+	chainID := ids.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	w, err := NewWallet(logging.NoLog{}, 12345, chainID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assetID := ids.Empty.Prefix(0)
+
+	addr, err := w.GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.AddUTXO(&avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(1)},
+		Asset:  avax.Asset{ID: assetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: 1000,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{addr},
+			},
+		},
+	})
+
+	// With 100 txs, cancellation is checked every 2 txs, so the third check
+	// happens after 4 txs have been generated.
+	ctx := &cancelAfterContext{
+		Context: context.Background(),
+		checks:  2,
+	}
+	if _, err := w.GenerateTxs(ctx, 100, []ids.ID{assetID}); err != context.Canceled {
+		t.Fatalf("expected %s, got %v", context.Canceled, err)
+	}
+
+	numTxs := 0
+	for tx := w.NextTx(); tx != nil; tx = w.NextTx() {
+		numTxs++
+	}
+	if numTxs != 4 {
+		t.Fatalf("expected 4 txs, generated %d", numTxs)
+	}
+}
+
+func TestWalletCreateMultiTx(t *testing.T) {
+	chainID := ids.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	w, err := NewWallet(logging.NoLog{}, 12345, chainID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assetID := ids.Empty.Prefix(0)
+
+	addr, err := w.GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.AddUTXO(&avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(1)},
+		Asset:  avax.Asset{ID: assetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: 1000,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{addr},
+			},
+		},
+	})
+
+	payments := []Payment{
+		{Addr: ids.NewShortID([20]byte{1}), Amount: 1},
+		{Addr: ids.NewShortID([20]byte{2}), Amount: 2},
+		{Addr: ids.NewShortID([20]byte{3}), Amount: 500},
+	}
+	tx, err := w.CreateMultiTx(assetID, payments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	received := make(map[[20]byte]uint64)
+	for _, utxo := range tx.UTXOs() {
+		out := utxo.Out.(*secp256k1fx.TransferOutput)
+		received[out.Addrs[0].Key()] += out.Amount()
+	}
+	for _, payment := range payments {
+		if amount := received[payment.Addr.Key()]; amount != payment.Amount {
+			t.Fatalf("expected %s to receive %d, received %d", payment.Addr, payment.Amount, amount)
+		}
+	}
+	if change := received[addr.Key()]; change != 497 {
+		t.Fatalf("expected change of 497, received %d", change)
+	}
+
+	if _, err := w.CreateMultiTx(assetID, []Payment{
+		{Addr: ids.NewShortID([20]byte{1}), Amount: 600},
+		{Addr: ids.NewShortID([20]byte{2}), Amount: 401},
+	}); err != ErrInsufficientFunds {
+		t.Fatalf("expected %s, got %v", ErrInsufficientFunds, err)
+	}
+	if _, err := w.CreateMultiTx(assetID, []Payment{
+		{Addr: ids.NewShortID([20]byte{1}), Amount: 1},
+		{Addr: ids.NewShortID([20]byte{2}), Amount: 0},
+	}); err != ErrAmountZero {
+		t.Fatalf("expected %s, got %v", ErrAmountZero, err)
+	}
+}
+
+func TestWalletCreateTxErrors(t *testing.T) {
+	chainID := ids.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	w, err := NewWallet(logging.NoLog{}, 12345, chainID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assetID := ids.Empty.Prefix(0)
+	destAddr := ids.NewShortID([20]byte{1})
+
+	if _, err := w.CreateTx(assetID, 1, destAddr); err != ErrNoKeys {
+		t.Fatalf("expected %s, got %v", ErrNoKeys, err)
+	}
+
+	addr, err := w.GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, amount := range []uint64{10, stdmath.MaxUint64} {
+		w.AddUTXO(&avax.UTXO{
+			UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(uint64(i) + 1)},
+			Asset:  avax.Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: amount,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{addr},
+				},
+			},
+		})
+	}
+
+	if _, err := w.CreateMultiTx(assetID, nil); err != ErrNoPayments {
+		t.Fatalf("expected %s, got %v", ErrNoPayments, err)
+	}
+	if _, err := w.CreateTx(assetID, 0, destAddr); err != ErrAmountZero {
+		t.Fatalf("expected %s, got %v", ErrAmountZero, err)
+	}
+	if _, err := w.CreateMultiTx(assetID, []Payment{
+		{Addr: destAddr, Amount: stdmath.MaxUint64},
+		{Addr: destAddr, Amount: 1},
+	}); err != ErrOverflow {
+		t.Fatalf("expected %s, got %v", ErrOverflow, err)
+	}
+	// Spending the 10 unit UTXO isn't enough, so the MaxUint64 UTXO is also
+	// spent, which overflows the amount spent.
+	if _, err := w.CreateTx(assetID, 11, destAddr); err != ErrOverflow {
+		t.Fatalf("expected %s, got %v", ErrOverflow, err)
+	}
+	if _, err := w.CreateTx(ids.Empty.Prefix(1), 1, destAddr); err != ErrInsufficientFunds {
+		t.Fatalf("expected %s, got %v", ErrInsufficientFunds, err)
+	}
+}
+
+func TestWalletCheckInvariants(t *testing.T) {
+	chainID := ids.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	w, err := NewWallet(logging.NoLog{}, 12345, chainID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assetID := ids.Empty.Prefix(0)
+
+	addr, err := w.GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.AddUTXO(&avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(1)},
+		Asset:  avax.Asset{ID: assetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: 1000,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{addr},
+			},
+		},
+	})
+	if err := w.checkInvariants(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.GenerateTxs(context.Background(), 10, []ids.ID{assetID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.checkInvariants(); err != nil {
+		t.Fatal(err)
+	}
+
+	w.balance[assetID]++
+	if err := w.checkInvariants(); err == nil {
+		t.Fatalf("should have detected the inconsistent balance")
+	}
+	w.balance[assetID]--
+
+	w.utxoSet.UTXOs[0], w.utxoSet.UTXOs[1] = w.utxoSet.UTXOs[1], w.utxoSet.UTXOs[0]
+	if err := w.checkInvariants(); err == nil {
+		t.Fatalf("should have detected the inconsistent UTXO index")
+	}
+	w.utxoSet.UTXOs[0], w.utxoSet.UTXOs[1] = w.utxoSet.UTXOs[1], w.utxoSet.UTXOs[0]
+
+	w.utxoSet.byAge.MoveToFront(w.utxoSet.byAge.Back())
+	if err := w.checkInvariants(); err == nil {
+		t.Fatalf("should have detected the inconsistent UTXO age order")
+	}
+}
+
+func TestWalletSpendOldestFirst(t *testing.T) {
+	chainID := ids.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	w, err := NewWallet(logging.NoLog{}, 12345, chainID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetSpendOldestFirst(true)
+
+	assetID := ids.Empty.Prefix(0)
+
+	addr, err := w.GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	utxos := []*avax.UTXO{}
+	for i := uint64(0); i < 3; i++ {
+		utxo := &avax.UTXO{
+			UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(i + 1)},
+			Asset:  avax.Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 1000,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{addr},
+				},
+			},
+		}
+		w.AddUTXO(utxo)
+		utxos = append(utxos, utxo)
+	}
+
+	// Removing the oldest UTXO moves the newest UTXO to the front of the
+	// UTXO list, so arbitrary selection would spend it next.
+	w.RemoveUTXO(utxos[0].InputID())
+
+	tx, err := w.CreateTx(assetID, 1, ids.NewShortID([20]byte{1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	consumed := tx.InputUTXOs()
+	if len(consumed) != 1 {
+		t.Fatalf("expected 1 input, found %d", len(consumed))
+	}
+	if id := consumed[0].InputID(); id != utxos[1].InputID() {
+		t.Fatalf("expected the oldest UTXO %s to be spent, spent %s", utxos[1].InputID(), id)
+	}
+}
+
+func TestWalletMinInputs(t *testing.T) {
+	chainID := ids.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	w, err := NewWallet(logging.NoLog{}, 12345, chainID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetMinInputs(3)
+
+	assetID := ids.Empty.Prefix(0)
+	destAddr := ids.NewShortID([20]byte{1})
+
+	addr, err := w.GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(0); i < 2; i++ {
+		w.AddUTXO(&avax.UTXO{
+			UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(i + 1)},
+			Asset:  avax.Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 1000,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{addr},
+				},
+			},
+		})
+	}
+
+	if _, err := w.CreateTx(assetID, 1, destAddr); err != ErrInsufficientInputs {
+		t.Fatalf("expected %s, got %v", ErrInsufficientInputs, err)
+	}
+
+	w.AddUTXO(&avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(3)},
+		Asset:  avax.Asset{ID: assetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: 1000,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{addr},
+			},
+		},
+	})
+
+	tx, err := w.CreateTx(assetID, 1, destAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numInputs := len(tx.InputUTXOs()); numInputs != 3 {
+		t.Fatalf("expected 3 inputs, found %d", numInputs)
+	}
+	if numCreds := len(tx.Creds); numCreds != 3 {
+		t.Fatalf("expected 3 credentials, found %d", numCreds)
+	}
+}
+
+func TestWalletGenerateTxsMultipleAssets(t *testing.T) {
+	chainID := ids.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	w, err := NewWallet(logging.NoLog{}, 12345, chainID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assetA := ids.Empty.Prefix(0)
+	assetB := ids.Empty.Prefix(1)
+	assetC := ids.Empty.Prefix(2) // Not held by the wallet
+
+	addr, err := w.GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, assetID := range []ids.ID{assetA, assetB} {
+		w.AddUTXO(&avax.UTXO{
+			UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(uint64(i) + 10)},
+			Asset:  avax.Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 1000,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{addr},
+				},
+			},
+		})
+	}
+
+	numTxsPerAsset, err := w.GenerateTxs(context.Background(), 5, []ids.ID{assetA, assetB, assetC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numTxs := numTxsPerAsset[assetA]; numTxs != 3 {
+		t.Fatalf("expected 3 txs of asset A, generated %d", numTxs)
+	}
+	if numTxs := numTxsPerAsset[assetB]; numTxs != 2 {
+		t.Fatalf("expected 2 txs of asset B, generated %d", numTxs)
+	}
+	if numTxs := numTxsPerAsset[assetC]; numTxs != 0 {
+		t.Fatalf("expected 0 txs of asset C, generated %d", numTxs)
+	}
+
+	expectedAssets := []ids.ID{assetA, assetB, assetA, assetB, assetA}
+	for i, expectedAssetID := range expectedAssets {
+		tx := w.NextTx()
+		if tx == nil {
+			t.Fatalf("expected tx %d to have been generated", i)
+		}
+		if assetID := tx.UTXOs()[0].AssetID(); assetID != expectedAssetID {
+			t.Fatalf("expected tx %d to send %s, sent %s", i, expectedAssetID, assetID)
+		}
+	}
+
+	if _, err := w.GenerateTxs(context.Background(), 5, nil); err != ErrNoAssets {
+		t.Fatalf("expected %s, got %v", ErrNoAssets, err)
+	}
+	if _, err := w.GenerateTxs(context.Background(), 5, []ids.ID{assetC}); err != ErrInsufficientFunds {
+		t.Fatalf("expected %s, got %v", ErrInsufficientFunds, err)
+	}
+	if tx := w.NextTx(); tx != nil {
+		t.Fatalf("shouldn't have generated any txs without funds")
+	}
+}
+
+func TestWalletGenerateTxsInsufficientInputs(t *testing.T) {
+	chainID := ids.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	w, err := NewWallet(logging.NoLog{}, 12345, chainID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetMinInputs(2)
+
+	assetA := ids.Empty.Prefix(0) // Held in too few UTXOs
+	assetB := ids.Empty.Prefix(1)
+
+	addr, err := w.GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, assetID := range []ids.ID{assetA, assetB, assetB, assetB} {
+		w.AddUTXO(&avax.UTXO{
+			UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(uint64(i) + 10)},
+			Asset:  avax.Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 1000,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{addr},
+				},
+			},
+		})
+	}
+
+	numTxsPerAsset, err := w.GenerateTxs(context.Background(), 5, []ids.ID{assetA, assetB})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numTxs := numTxsPerAsset[assetA]; numTxs != 0 {
+		t.Fatalf("expected 0 txs of asset A, generated %d", numTxs)
+	}
+	if numTxs := numTxsPerAsset[assetB]; numTxs != 5 {
+		t.Fatalf("expected 5 txs of asset B, generated %d", numTxs)
+	}
+}
+
+func TestWalletCreateTxFromInputs(t *testing.T) {
+	chainID := ids.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	w, err := NewWallet(logging.NoLog{}, 12345, chainID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assetID := ids.Empty.Prefix(0)
+	payments := []Payment{{Addr: ids.NewShortID([20]byte{1}), Amount: 1500}}
+
+	if _, err := w.CreateTxFromInputs(assetID, nil, payments); err != ErrNoKeys {
+		t.Fatalf("expected %s, got %v", ErrNoKeys, err)
+	}
+
+	addr, err := w.GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	utxos := []*avax.UTXO{}
+	for i := uint64(0); i < 3; i++ {
+		utxo := &avax.UTXO{
+			UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(i + 1)},
+			Asset:  avax.Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 1000,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{addr},
+				},
+			},
+		}
+		w.AddUTXO(utxo)
+		utxos = append(utxos, utxo)
+	}
+
+	tx, err := w.CreateTxFromInputs(assetID, []avax.UTXOID{utxos[2].UTXOID, utxos[0].UTXOID}, payments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumed := tx.InputUTXOs()
+	if len(consumed) != 2 {
+		t.Fatalf("expected 2 inputs, found %d", len(consumed))
+	}
+	for _, utxoID := range consumed {
+		if id := utxoID.InputID(); id != utxos[0].InputID() && id != utxos[2].InputID() {
+			t.Fatalf("unexpectedly consumed UTXO %s", id)
+		}
+	}
+	if outs := tx.UTXOs(); len(outs) != 2 {
+		t.Fatalf("expected a payment and a change output, found %d outputs", len(outs))
+	}
+
+	if _, err := w.CreateTxFromInputs(assetID, []avax.UTXOID{utxos[0].UTXOID}, payments); err != ErrInsufficientFunds {
+		t.Fatalf("expected %s, got %v", ErrInsufficientFunds, err)
+	}
+	if _, err := w.CreateTxFromInputs(assetID, []avax.UTXOID{utxos[0].UTXOID, utxos[0].UTXOID}, payments); !errors.Is(err, ErrDuplicateInput) {
+		t.Fatalf("expected %s, got %v", ErrDuplicateInput, err)
+	}
+	unknown := avax.UTXOID{TxID: ids.Empty.Prefix(100)}
+	if _, err := w.CreateTxFromInputs(assetID, []avax.UTXOID{utxos[0].UTXOID, unknown}, payments); !errors.Is(err, ErrUnknownUTXO) {
+		t.Fatalf("expected %s, got %v", ErrUnknownUTXO, err)
+	}
+	if _, err := w.CreateTxFromInputs(ids.Empty.Prefix(1), []avax.UTXOID{utxos[0].UTXOID}, payments); !errors.Is(err, ErrWrongAsset) {
+		t.Fatalf("expected %s, got %v", ErrWrongAsset, err)
+	}
+}
+
+func TestWalletChangeSplits(t *testing.T) {
+	chainID := ids.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	w, err := NewWallet(logging.NoLog{}, 12345, chainID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetChangeSplits(3)
+
+	assetID := ids.Empty.Prefix(0)
+	destAddr := ids.NewShortID([20]byte{1})
+
+	addr, err := w.GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.AddUTXO(&avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(1)},
+		Asset:  avax.Asset{ID: assetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: 1000,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{addr},
+			},
+		},
+	})
+
+	// The change of 998 doesn't split evenly into 3 outputs
+	tx, err := w.CreateTx(assetID, 2, destAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	numChangeOuts := 0
+	change := uint64(0)
+	for _, utxo := range tx.UTXOs() {
+		out := utxo.Out.(*secp256k1fx.TransferOutput)
+		if !out.Addrs[0].Equals(addr) {
+			continue
+		}
+		numChangeOuts++
+		change += out.Amount()
+	}
+	if numChangeOuts != 3 {
+		t.Fatalf("expected 3 change outputs, found %d", numChangeOuts)
+	}
+	if change != 998 {
+		t.Fatalf("expected change of 998, found %d", change)
+	}
+
+	// The change of 2 is too small to be split into 3 outputs
+	tx, err = w.CreateTx(assetID, 998, destAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outs := tx.UTXOs(); len(outs) != 3 {
+		t.Fatalf("expected a payment and 2 change outputs, found %d outputs", len(outs))
+	}
+}
+
+func TestWalletGenerateTxsChangeSplits(t *testing.T) {
+	chainID := ids.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	w, err := NewWallet(logging.NoLog{}, 12345, chainID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetChangeSplits(3)
+
+	assetID := ids.Empty.Prefix(0)
+
+	addr, err := w.GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.AddUTXO(&avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(1)},
+		Asset:  avax.Asset{ID: assetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: 1000,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{addr},
+			},
+		},
+	})
+
+	// Each tx spends a UTXO that eventually becomes too small to be split
+	numTxsPerAsset, err := w.GenerateTxs(context.Background(), 200, []ids.ID{assetID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numTxs := numTxsPerAsset[assetID]; numTxs != 200 {
+		t.Fatalf("expected 200 txs, generated %d", numTxs)
+	}
+	if balance := w.Balance(assetID); balance != 1000 {
+		t.Fatalf("expected balance to be 1000, was %d", balance)
+	}
+	if numUTXOs := len(w.utxoSet.UTXOs); numUTXOs <= 1 {
+		t.Fatalf("expected the change splits to grow the UTXO set, found %d UTXOs", numUTXOs)
+	}
+	if err := w.checkInvariants(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWalletExportKeys(t *testing.T) {
+	chainID := ids.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	w, err := NewWallet(logging.NoLog{}, 12345, chainID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	factory := crypto.FactorySECP256K1R{}
+	sk, err := factory.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.ImportKey(sk.(*crypto.PrivateKeySECP256K1R))
+
+	createdAddr, err := w.CreateAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if keys := w.ExportKeys(false); len(keys) != 2 {
+		t.Fatalf("expected 2 keys, exported %d", len(keys))
+	}
+
+	keys := w.ExportKeys(true)
+	if len(keys) != 1 {
+		t.Fatalf("expected 1 key, exported %d", len(keys))
+	}
+	if addr := keys[0].PublicKey().Address(); !addr.Equals(createdAddr) {
+		t.Fatalf("expected key for %s, exported key for %s", createdAddr, addr)
+	}
+}
+
+func TestWalletMinChange(t *testing.T) {
+	chainID := ids.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	w, err := NewWallet(logging.NoLog{}, 12345, chainID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetMinChange(10)
+
+	assetID := ids.Empty.Prefix(0)
+	destAddr := ids.NewShortID([20]byte{1})
+
+	addr, err := w.GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	utxos := []*avax.UTXO{}
+	for i := uint64(0); i < 2; i++ {
+		utxo := &avax.UTXO{
+			UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(i + 1)},
+			Asset:  avax.Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 1000,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{addr},
+				},
+			},
+		}
+		w.AddUTXO(utxo)
+		utxos = append(utxos, utxo)
+	}
+
+	// Spending one UTXO would leave change of 5, so another UTXO is spent
+	tx, err := w.CreateTx(assetID, 995, destAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numInputs := len(tx.InputUTXOs()); numInputs != 2 {
+		t.Fatalf("expected 2 inputs, found %d", numInputs)
+	}
+	change := uint64(0)
+	for _, utxo := range tx.UTXOs() {
+		if out := utxo.Out.(*secp256k1fx.TransferOutput); out.Addrs[0].Equals(addr) {
+			change += out.Amount()
+		}
+	}
+	if change != 1005 {
+		t.Fatalf("expected change of 1005, found %d", change)
+	}
+	if burned := w.Burned(); burned != 0 {
+		t.Fatalf("expected nothing to be burned, burned %d", burned)
+	}
+
+	// No other UTXO can raise the change of 5, so it is burned
+	tx, err = w.CreateTxFromInputs(assetID, []avax.UTXOID{utxos[0].UTXOID}, []Payment{{Addr: destAddr, Amount: 995}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outs := tx.UTXOs(); len(outs) != 1 {
+		t.Fatalf("expected only the payment output, found %d outputs", len(outs))
+	}
+	if burned := w.Burned(); burned != 5 {
+		t.Fatalf("expected 5 to be burned, burned %d", burned)
+	}
+
+	// A change of 15 can't be split into 3 outputs of at least 10, so a single
+	// output is created
+	w.SetChangeSplits(3)
+	tx, err = w.CreateTxFromInputs(assetID, []avax.UTXOID{utxos[0].UTXOID}, []Payment{{Addr: destAddr, Amount: 985}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outs := tx.UTXOs(); len(outs) != 2 {
+		t.Fatalf("expected a payment and a change output, found %d outputs", len(outs))
+	}
+	w.SetChangeSplits(0)
+
+	if _, err := w.CreateTx(assetID, 9, destAddr); err != ErrMinChangeTooHigh {
+		t.Fatalf("expected %s, got %v", ErrMinChangeTooHigh, err)
+	}
+
+	w.RemoveUTXO(utxos[1].InputID())
+	tx, err = w.CreateTx(assetID, 991, destAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outs := tx.UTXOs(); len(outs) != 1 {
+		t.Fatalf("expected only the payment output, found %d outputs", len(outs))
+	}
+	if burned := w.Burned(); burned != 14 {
+		t.Fatalf("expected 14 to be burned, burned %d", burned)
+	}
+}
+
+func TestWalletChangeOwners(t *testing.T) {
+	chainID := ids.ID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	w, err := NewWallet(logging.NoLog{}, 12345, chainID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assetID := ids.Empty.Prefix(0)
+	destAddr := ids.NewShortID([20]byte{1})
+
+	addr0, err := w.GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr1, err := w.CreateAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.AddUTXO(&avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.Empty.Prefix(1)},
+		Asset:  avax.Asset{ID: assetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: 1000,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{addr0},
+			},
+		},
+	})
+
+	changeAddrs := []ids.ShortID{addr0, addr1}
+	ids.SortShortIDs(changeAddrs)
+	if err := w.SetChangeOwners(&secp256k1fx.OutputOwners{
+		Threshold: 2,
+		Addrs:     changeAddrs,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := w.CreateTx(assetID, 1, destAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, utxoID := range tx.InputUTXOs() {
+		w.RemoveUTXO(utxoID.InputID())
+	}
+	for _, utxo := range tx.UTXOs() {
+		w.AddUTXO(utxo)
+	}
+	if balance := w.Balance(assetID); balance != 999 {
+		t.Fatalf("expected the multisig change of 999 to be held, held %d", balance)
+	}
+
+	// Spending the multisig change requires a signature from both keys
+	tx, err = w.CreateTx(assetID, 1, destAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.Creds) != 1 {
+		t.Fatalf("expected 1 credential, found %d", len(tx.Creds))
+	}
+	if numSigs := len(tx.Creds[0].(*secp256k1fx.Credential).Sigs); numSigs != 2 {
+		t.Fatalf("expected 2 signatures, found %d", numSigs)
+	}
+
+	// Changing the caller's owners shouldn't change the change owners
+	changeAddrs[0] = destAddr
+	tx, err = w.CreateTx(assetID, 1, destAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, utxo := range tx.UTXOs() {
+		if out := utxo.Out.(*secp256k1fx.TransferOutput); out.Amount() != 1 && out.Addrs[0].Equals(destAddr) {
+			t.Fatalf("change shouldn't be sent to %s", destAddr)
+		}
+	}
+
+	// The wallet doesn't hold the key for [destAddr], so it can't spend
+	// change that requires its signature
+	unspendableAddrs := []ids.ShortID{addr0, destAddr}
+	ids.SortShortIDs(unspendableAddrs)
+	if err := w.SetChangeOwners(&secp256k1fx.OutputOwners{
+		Threshold: 2,
+		Addrs:     unspendableAddrs,
+	}); !errors.Is(err, ErrInvalidChangeOwners) {
+		t.Fatalf("expected %s, got %v", ErrInvalidChangeOwners, err)
+	}
+	if err := w.SetChangeOwners(&secp256k1fx.OutputOwners{
+		Threshold: 3,
+		Addrs:     []ids.ShortID{addr0},
+	}); !errors.Is(err, ErrInvalidChangeOwners) {
+		t.Fatalf("expected %s, got %v", ErrInvalidChangeOwners, err)
+	}
+	if err := w.SetChangeOwners(&secp256k1fx.OutputOwners{}); !errors.Is(err, ErrInvalidChangeOwners) {
+		t.Fatalf("expected %s, got %v", ErrInvalidChangeOwners, err)
+	}
+	if err := w.SetChangeOwners(&secp256k1fx.OutputOwners{
+		Locktime:  w.clock.Unix() + 1,
+		Threshold: 1,
+		Addrs:     []ids.ShortID{addr0},
+	}); !errors.Is(err, ErrInvalidChangeOwners) {
+		t.Fatalf("expected %s, got %v", ErrInvalidChangeOwners, err)
+	}
+}